@@ -0,0 +1,77 @@
+package main
+
+import "go-scoundrel/solver"
+
+// toSolverState converts the live model into the solver's view of the
+// game so solver.Solve can be asked for the optimal next move. Suit is
+// dropped (the solver doesn't need it) and spell cards from Wizard-mode
+// rulesets are simply omitted - the solver doesn't model mana or spell
+// timers, so a hint in that ruleset only reasons about the
+// monster/weapon/potion subset of the room and dungeon.
+func (m *model) toSolverState() solver.State {
+	return solver.State{
+		Health:             m.health,
+		HealthCap:          m.ruleset.HealthCap,
+		Room:               solverCards(m.room),
+		RoomSize:           m.ruleset.RoomSize,
+		HasWeapon:          (m.equippedWeapon != Card{}),
+		WeaponValue:        m.equippedWeapon.Value,
+		WeaponLimit:        m.weaponLimit,
+		MaxWeaponLimit:     m.ruleset.WeaponLimit,
+		AvoidedLastRoom:    m.avoidedLastRoom,
+		PotionUsedThisTurn: m.potionsUsedThisTurn >= m.ruleset.PotionsPerTurn,
+		Dungeon:            solverCards(m.dungeon),
+	}
+}
+
+// solverCards filters and converts a slice of the game's Card type to the
+// solver's, dropping spell cards: the solver only reasons about combat.
+func solverCards(cards []Card) []solver.Card {
+	out := make([]solver.Card, 0, len(cards))
+	for _, c := range cards {
+		switch c.Type {
+		case "Monster", "Weapon", "Potion":
+			out = append(out, solver.Card{Value: c.Value, Type: c.Type})
+		}
+	}
+	return out
+}
+
+// hintDescription renders a solver.Result as the short line View shows
+// under the room.
+func hintDescription(result solver.Result) string {
+	return result.Action.String()
+}
+
+// applySolverAction replays a solver-recommended action against the live
+// model, for --simulate's headless play. It mirrors the key handlers in
+// Update, minus the undo/network/logging side effects those don't need
+// outside an interactive session.
+func (m *model) applySolverAction(action solver.Action) {
+	switch action.Kind {
+	case solver.ActionNone:
+		// The solver signaled a chance node (the room is short a card and
+		// the dungeon isn't empty): deal, same as the live 'd' key.
+		m.dealRoom()
+
+	case solver.ActionAvoid:
+		for _, card := range m.room {
+			m.dungeon = append(m.dungeon, card)
+		}
+		m.room = []Card{}
+		m.dealRoom()
+		m.avoidedLastRoom = true
+
+	case solver.ActionPick:
+		card := m.room[action.RoomIndex]
+		if card.Type == "Monster" {
+			m.selectedCard = action.RoomIndex
+			m.cardsChosen++
+			m.fightingBarehanded = action.Barehanded
+			m.choosingFight = true
+			m.finishFight()
+			return
+		}
+		m.selectCard(action.RoomIndex)
+	}
+}
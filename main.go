@@ -1,24 +1,46 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
 	"os"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	scoundrelnet "go-scoundrel/net"
+	"go-scoundrel/solver"
 )
 
 var debugMode = false // Enable or disable debug mode
 
+// selectedRuleset is the ruleset new games start with, set once from the
+// --ruleset flag in main before any model is created.
+var selectedRuleset = defaultRuleset
+
+// GameStatus tracks which screen the model is currently presenting, so that
+// lobby, in-progress, and end-of-run states don't have to be reconstructed
+// from ad-hoc health/room checks scattered through Update and View.
+type GameStatus int
+
+const (
+	StatusLobby GameStatus = iota
+	StatusPlaying
+	StatusWin
+	StatusGameOver
+)
+
 type Card struct {
 	Suit      string
 	Value     int
-	Type      string // "Monster", "Weapon", "Potion"
+	Type      string // "Monster", "Weapon", "Potion", or a spell type such as "Shield"/"Poison"/"Recharge"
 	MonsterValue int // Value of the monster slain by this weapon
+	ManaCost  int // Mana required to cast a spell card; zero for non-spell cards
 }
 
 type model struct {
+	status         GameStatus
 	health         int
 	dungeon        []Card
 	room           []Card
@@ -30,39 +52,174 @@ type model struct {
 	choosingFight  bool          // True if the player is choosing how to fight
 	fightingBarehanded bool // True if the player chose to fight barehanded
 	avoidedLastRoom bool          // True if the player avoided the room last turn
-	potionUsedThisTurn bool
+	potionsUsedThisTurn int
+
+	endlessMode     bool // True once the player has opted into campaign mode from the lobby
+	dungeonClears   int  // Number of dungeons fully cleared this run
+	cumulativeScore int  // Score carried over across cleared dungeons in endless mode
+
+	ruleset Ruleset // Tunable constants and card pack for the active game variant
+
+	mana         int // Spent casting spell cards in Wizard mode rulesets
+	shieldTurns  int // Remaining fights with incoming damage reduced
+	poisonTurns  int // Remaining turns of poison damage ticking at the start of a room
+	rechargeTurns int // Remaining turns of bonus mana regeneration
+
+	undoStack []undoSnapshot // Recent state snapshots, most recent last
+
+	seed int64    // RNG seed for this run, recorded so a replay can reproduce the deck
+	rng  *rand.Rand
+
+	networked bool                // True once this model is playing over the net package
+	netConn   *scoundrelnet.Conn  // Connection to the opponent, nil when not networked
+
+	opponentHealth       int
+	opponentDungeonCount int
+	opponentDiscardCount int
+	opponentWeapon       Card
+	opponentScore        int
+	opponentLastAction   string
+
+	hint      *solver.Result // Last computed hint, nil until 'h' is pressed; cleared on any other action
+
+	saveMessage string // Feedback from the last save/load/log attempt; cleared on any other action
+	replaying   bool   // True while runReplay is driving Update, to suppress logging and disk I/O
+}
+
+// maxUndoDepth bounds the undo stack so a long session doesn't retain an
+// ever-growing history of deep-copied state.
+const maxUndoDepth = 20
+
+// undoSnapshot captures everything a mutating action can change, so popping
+// it restores the model exactly as it was before that action ran.
+type undoSnapshot struct {
+	health              int
+	dungeon             []Card
+	room                []Card
+	equippedWeapon      Card
+	discardPile         []Card
+	weaponLimit         int
+	avoidedLastRoom     bool
+	potionsUsedThisTurn int
+	cardsChosen         int
+	selectedCard        int
+	choosingFight       bool
+	dungeonClears       int
+	cumulativeScore     int
+	mana                int
+	shieldTurns         int
+	poisonTurns         int
+	rechargeTurns       int
+}
+
+// pushUndo snapshots the current mutable state before an action is applied.
+func (m *model) pushUndo() {
+	snapshot := undoSnapshot{
+		health:              m.health,
+		dungeon:             append([]Card{}, m.dungeon...),
+		room:                append([]Card{}, m.room...),
+		equippedWeapon:      m.equippedWeapon,
+		discardPile:         append([]Card{}, m.discardPile...),
+		weaponLimit:         m.weaponLimit,
+		avoidedLastRoom:     m.avoidedLastRoom,
+		potionsUsedThisTurn: m.potionsUsedThisTurn,
+		cardsChosen:         m.cardsChosen,
+		selectedCard:        m.selectedCard,
+		choosingFight:       m.choosingFight,
+		dungeonClears:       m.dungeonClears,
+		cumulativeScore:     m.cumulativeScore,
+		mana:                m.mana,
+		shieldTurns:         m.shieldTurns,
+		poisonTurns:         m.poisonTurns,
+		rechargeTurns:       m.rechargeTurns,
+	}
+
+	m.undoStack = append(m.undoStack, snapshot)
+	if len(m.undoStack) > maxUndoDepth {
+		m.undoStack = m.undoStack[1:]
+	}
+}
+
+// popUndo restores the most recent snapshot, if any, undoing the last action.
+func (m *model) popUndo() {
+	if len(m.undoStack) == 0 {
+		return
+	}
+
+	snapshot := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+
+	m.health = snapshot.health
+	m.dungeon = snapshot.dungeon
+	m.room = snapshot.room
+	m.equippedWeapon = snapshot.equippedWeapon
+	m.discardPile = snapshot.discardPile
+	m.weaponLimit = snapshot.weaponLimit
+	m.avoidedLastRoom = snapshot.avoidedLastRoom
+	m.mana = snapshot.mana
+	m.shieldTurns = snapshot.shieldTurns
+	m.poisonTurns = snapshot.poisonTurns
+	m.rechargeTurns = snapshot.rechargeTurns
+	m.potionsUsedThisTurn = snapshot.potionsUsedThisTurn
+	m.cardsChosen = snapshot.cardsChosen
+	m.selectedCard = snapshot.selectedCard
+	m.choosingFight = snapshot.choosingFight
+	m.dungeonClears = snapshot.dungeonClears
+	m.cumulativeScore = snapshot.cumulativeScore
 }
 
 func initialModel() *model {
-	// Initialize the random number generator
-	rand.Seed(time.Now().UnixNano())
+	return &model{
+		status:       StatusLobby,
+		selectedCard: -1,
+		weaponLimit:  14,
+		ruleset:      selectedRuleset,
+	}
+}
 
-	// Create the deck
-	deck := createDeck()
+// startGame leaves the lobby and deals a fresh dungeon, optionally opting
+// into endless/campaign mode, seeded from the current time.
+func (m *model) startGame(endless bool) {
+	m.startGameWithSeed(endless, time.Now().UnixNano())
+}
+
+// startGameWithSeed is like startGame but seeds the shuffle explicitly, so a
+// recorded action log can be replayed against the exact same deck order.
+func (m *model) startGameWithSeed(endless bool, seed int64) {
+	m.seed = seed
+	m.rng = rand.New(rand.NewSource(seed))
 
-	// Shuffle the deck
-	rand.Shuffle(len(deck), func(i, j int) {
+	if m.ruleset.CardPack == nil {
+		m.ruleset = defaultRuleset
+	}
+	deck := m.ruleset.CardPack.BuildDeck()
+	m.rng.Shuffle(len(deck), func(i, j int) {
 		deck[i], deck[j] = deck[j], deck[i]
 	})
 
-	m := &model{
-		health:         20,
-		dungeon:        deck,
-		room:           []Card{},
-		equippedWeapon: Card{}, // Empty card
-		discardPile:    []Card{},
-		selectedCard:   -1,      // -1 means no card is selected
-		cardsChosen:    0,
-		weaponLimit:    14,      // Can use weapon on any monster to start
-		choosingFight:  false, // Player is not choosing how to fight
-		fightingBarehanded: false,
-		avoidedLastRoom: false,
-		potionUsedThisTurn: false,
-	}
-
-	// Deal initial room
+	m.status = StatusPlaying
+	m.endlessMode = endless
+	m.health = m.ruleset.HealthCap
+	m.dungeon = deck
+	m.room = []Card{}
+	m.equippedWeapon = Card{}
+	m.discardPile = []Card{}
+	m.selectedCard = -1
+	m.cardsChosen = 0
+	m.weaponLimit = m.ruleset.WeaponLimit
+	m.choosingFight = false
+	m.fightingBarehanded = false
+	m.avoidedLastRoom = false
+	m.potionsUsedThisTurn = 0
+	m.dungeonClears = 0
+	m.cumulativeScore = 0
+	m.undoStack = nil
+	m.mana = m.ruleset.StartingMana
+	m.shieldTurns = 0
+	m.poisonTurns = 0
+	m.rechargeTurns = 0
+
 	m.dealRoom()
-	return m
 }
 
 func createDeck() []Card {
@@ -117,8 +274,8 @@ func (m *model) calculateScore() int {
 
 	// If you have made your way through the entire dungeon, your score is your positive life
 	score := m.health
-	// If your life is 20, and your last card was a health potion, your life + the value of that potion.
-	if m.health == 20 && len(m.discardPile) > 0 && m.discardPile[len(m.discardPile)-1].Type == "Potion" {
+	// If your life is at the cap, and your last card was a health potion, your life + the value of that potion.
+	if m.health == m.ruleset.HealthCap && len(m.discardPile) > 0 && m.discardPile[len(m.discardPile)-1].Type == "Potion" {
 		score += m.discardPile[len(m.discardPile)-1].Value
 	}
 	return score
@@ -126,22 +283,93 @@ func (m *model) calculateScore() int {
 
 func (m *model) dealRoom() {
 	m.avoidedLastRoom = false // Reset avoidedLastRoom at the start of the turn
-	m.potionUsedThisTurn = false
-
-	// Deal cards from the dungeon to the room until there are 4 cards
-	for len(m.room) < 4 {
-		if len(m.dungeon) > 0 {
-			card := m.dungeon[0]
-			m.dungeon = m.dungeon[1:]
-			m.room = append(m.room, card)
-		} else {
-			// Dungeon is empty, handle this case (e.g., reshuffle discard pile)
-			fmt.Println("Dungeon is empty!") // For now, just print a message
-			break                               // Stop dealing if the dungeon is empty
-		}
+	m.potionsUsedThisTurn = 0
+	m.tickSpellEffects()
+	if m.status == StatusGameOver {
+		return
+	}
+
+	// Deal cards from the dungeon to the room until the room is full
+	for len(m.room) < m.ruleset.RoomSize && len(m.dungeon) > 0 {
+		card := m.dungeon[0]
+		m.dungeon = m.dungeon[1:]
+		m.room = append(m.room, card)
 	}
 	m.cardsChosen = 0
 	m.selectedCard = -1
+
+	// Both the dungeon and the room are empty: the dungeon has been cleared.
+	if len(m.dungeon) == 0 && len(m.room) == 0 {
+		m.onDungeonCleared()
+	}
+}
+
+// maybeDealRoom deals a new room once three cards have been resolved from
+// the current one, matching dealRoom's own carry-the-last-card behavior.
+// If resolving the card instead emptied the room with no dungeon left to
+// deal from, the dungeon has been cleared even though dealRoom never ran.
+func (m *model) maybeDealRoom() {
+	if len(m.room) == 0 && len(m.dungeon) == 0 {
+		m.onDungeonCleared()
+		return
+	}
+	if m.ruleset.RoomSize-len(m.room) == 3 {
+		m.dealRoom()
+	}
+}
+
+// tickSpellEffects applies poison and recharge at the start of a room and
+// counts down their durations; shield turns are counted down per fight in
+// finishFight instead. Wizard-mode rulesets are the only ones that ever
+// set these above zero.
+func (m *model) tickSpellEffects() {
+	if m.poisonTurns > 0 {
+		m.health -= wizardPoisonDamagePerTurn
+		m.poisonTurns--
+		if m.health <= 0 {
+			m.status = StatusGameOver
+		}
+	}
+	if m.rechargeTurns > 0 {
+		m.mana += wizardRechargeManaPerTurn
+		m.rechargeTurns--
+	}
+}
+
+// onDungeonCleared is called once a dungeon has been fully played out with
+// the player still alive. In classic mode that's a win; in endless/campaign
+// mode the discard pile is reshuffled back into a new dungeon and play
+// continues, with the clear tallied toward a cumulative score.
+func (m *model) onDungeonCleared() {
+	if m.health <= 0 {
+		return
+	}
+
+	m.dungeonClears++
+	m.cumulativeScore += m.calculateScore()
+
+	if m.endlessMode {
+		m.reshuffleDiscardIntoDungeon()
+		m.dealRoom()
+		return
+	}
+
+	m.status = StatusWin
+}
+
+func (m *model) reshuffleDiscardIntoDungeon() {
+	m.dungeon = append(m.dungeon, m.discardPile...)
+	m.discardPile = []Card{}
+	m.rng.Shuffle(len(m.dungeon), func(i, j int) {
+		m.dungeon[i], m.dungeon[j] = m.dungeon[j], m.dungeon[i]
+	})
+	m.room = []Card{}
+	m.equippedWeapon = Card{}
+	m.weaponLimit = m.ruleset.WeaponLimit
+	m.mana = m.ruleset.StartingMana
+	m.shieldTurns = 0
+	m.poisonTurns = 0
+	m.rechargeTurns = 0
 }
 
 func (m *model) discard(card Card) {
@@ -157,22 +385,13 @@ func (m *model) equipWeapon(card Card) {
 }
 
 func (m *model) usePotion(card Card) {
-	if !m.potionUsedThisTurn {
+	if m.potionsUsedThisTurn < m.ruleset.PotionsPerTurn {
 		m.health += card.Value
-		if m.health > 20 {
-			m.health = 20
+		if m.health > m.ruleset.HealthCap {
+			m.health = m.ruleset.HealthCap
 		}
 		m.discard(card)
-		m.potionUsedThisTurn = true
-
-		// Remove the card from the room
-		m.room = append(m.room[:index], m.room[index+1:]...)
-
-		// If 3 cards have been chosen (or removed), deal a new room
-		if 4-len(m.room) == 3 {
-			m.dealRoom()
-		}
-
+		m.potionsUsedThisTurn++
 	} else {
 		m.discard(card) // Discard the potion without using it
 	}
@@ -182,6 +401,27 @@ func (m *model) fightMonster(card Card) {
 	m.choosingFight = true
 }
 
+// castSpell is the Wizard-mode ruleset's handler for Shield/Poison/Recharge
+// cards: if enough mana is banked, it spends it and arms the matching
+// timer; otherwise the card just fizzles into the discard pile.
+func (m *model) castSpell(card Card) {
+	if m.mana < card.ManaCost {
+		m.discard(card)
+		return
+	}
+
+	m.mana -= card.ManaCost
+	switch card.Type {
+	case "Shield":
+		m.shieldTurns = card.Value
+	case "Poison":
+		m.poisonTurns = card.Value
+	case "Recharge":
+		m.rechargeTurns = card.Value
+	}
+	m.discard(card)
+}
+
 func (m *model) finishFight() (tea.Model, tea.Cmd) {
 	// Check if the selected card is still valid
 	if m.selectedCard < 0 || m.selectedCard >= len(m.room) {
@@ -191,23 +431,27 @@ func (m *model) finishFight() (tea.Model, tea.Cmd) {
 
 	card := m.room[m.selectedCard]
 
+	damage := 0
 	if m.fightingBarehanded {
-		m.health -= card.Value
+		damage = card.Value
 	} else {
 		if (m.equippedWeapon == Card{}) {
-			m.health -= card.Value
-		} else {
-			// Check if the weapon can be used
-			if card.Value > m.weaponLimit {
-				m.health -= card.Value // Fight barehanded
-			} else {
-				damage := card.Value - m.equippedWeapon.Value
-				if damage > 0 {
-					m.health -= damage
-				}
-			}
+			damage = card.Value
+		} else if card.Value > m.weaponLimit {
+			damage = card.Value // Can't use the weapon on this monster, fight barehanded
+		} else if blow := card.Value - m.equippedWeapon.Value; blow > 0 {
+			damage = blow
+		}
+	}
+
+	if m.shieldTurns > 0 {
+		damage -= wizardShieldBlockAmount
+		if damage < 0 {
+			damage = 0
 		}
+		m.shieldTurns--
 	}
+	m.health -= damage
 
 	if !m.fightingBarehanded && (m.equippedWeapon != Card{}) && (card.Value <= m.weaponLimit) {
 		m.weaponLimit = card.Value                  // Update weapon limit *after* the fight
@@ -220,26 +464,105 @@ func (m *model) finishFight() (tea.Model, tea.Cmd) {
 	m.selectedCard = -1
 	m.choosingFight = false
 
-	// If 3 cards have been chosen (or removed), deal a new room
-	if 4-len(m.room) == 3 {
-		m.dealRoom()
+	if m.health <= 0 {
+		m.status = StatusGameOver
+		return m, nil
 	}
+
+	// If 3 cards have been chosen (or removed), deal a new room
+	m.maybeDealRoom()
 	return m, nil
 }
 
 func (m *model) Init() tea.Cmd {
+	if m.networked {
+		return listenForOpponent(m.netConn)
+	}
 	return nil
 }
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case opponentMsg:
+		m.applyOpponentMessage(scoundrelnet.Message(msg))
+		return m, listenForOpponent(m.netConn)
+	case opponentGoneMsg:
+		m.networked = false
+		m.netConn = nil
+		return m, nil
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
+		case "t":
+			debugMode = !debugMode
+			return m, nil
+		}
+
+		if m.status == StatusLobby {
+			switch msg.String() {
+			case "c":
+				m.startGame(false)
+				logGameStart(msg.String(), m.seed)
+			case "e":
+				m.startGame(true)
+				logGameStart(msg.String(), m.seed)
+			}
+			return m, nil
+		}
+
+		if m.status == StatusWin || m.status == StatusGameOver {
+			if msg.String() == "r" {
+				return initialModel(), nil // Back to the lobby for a new run
+			}
+			return m, nil
+		}
+
+		if msg.String() != "h" {
+			m.hint = nil
+		}
+		if msg.String() != "s" && msg.String() != "l" {
+			m.saveMessage = ""
+		}
+
+		if !m.replaying {
+			if err := logAction(msg.String()); err != nil {
+				m.saveMessage = fmt.Sprintf("Failed to record action log: %v", err)
+			}
+		}
+
+		switch msg.String() {
+		case "u":
+			m.popUndo()
+			return m, nil
+		case "s":
+			if m.replaying {
+				return m, nil
+			}
+			if err := m.saveGame(); err != nil {
+				m.saveMessage = fmt.Sprintf("Save failed: %v", err)
+			} else {
+				m.saveMessage = "Game saved."
+			}
+			return m, nil
+		case "l":
+			if m.replaying {
+				return m, nil
+			}
+			if err := m.loadGame(); err != nil {
+				m.saveMessage = fmt.Sprintf("Load failed: %v", err)
+			} else {
+				m.saveMessage = "Game loaded."
+			}
+			return m, nil
+		case "h":
+			result := solver.Solve(m.toSolverState())
+			m.hint = &result
+			return m, nil
 		case "a":
 			// Avoid the room
-			if !m.avoidedLastRoom {
+			if !m.ruleset.AvoidCooldown || !m.avoidedLastRoom {
+				m.pushUndo()
 				// Place the cards at the bottom of the dungeon
 				for _, card := range m.room {
 					m.dungeon = append(m.dungeon, card)
@@ -248,42 +571,54 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.room = []Card{}
 				m.dealRoom()
 				m.avoidedLastRoom = true // Mark that the room was avoided
+				m.sendOpponentAction(scoundrelnet.MsgAvoid, struct{}{})
+				m.syncOpponentState()
 				return m, nil
 			}
 		case "d":
+			m.pushUndo()
 			m.dealRoom()
 			return m, nil
 		case "1":
-			return m.selectCard(0), nil
+			m.pushUndo()
+			result := m.selectCard(0)
+			m.syncOpponentState()
+			return result, nil
 		case "2":
-			return m.selectCard(1), nil
+			m.pushUndo()
+			result := m.selectCard(1)
+			m.syncOpponentState()
+			return result, nil
 		case "3":
-			return m.selectCard(2), nil
+			m.pushUndo()
+			result := m.selectCard(2)
+			m.syncOpponentState()
+			return result, nil
 		case "4":
-			return m.selectCard(3), nil
+			m.pushUndo()
+			result := m.selectCard(3)
+			m.syncOpponentState()
+			return result, nil
 
 		// Handle choosing to fight barehanded or with a weapon
 		case "b":
 			if m.choosingFight {
+				m.pushUndo()
 				m.fightingBarehanded = true
+				m.sendOpponentAction(scoundrelnet.MsgFight, scoundrelnet.FightPayload{Barehanded: true})
 				model, cmd := m.finishFight()
+				m.syncOpponentState()
 				return model, cmd
 			}
 		case "w":
 			if m.choosingFight {
+				m.pushUndo()
 				m.fightingBarehanded = false
+				m.sendOpponentAction(scoundrelnet.MsgFight, scoundrelnet.FightPayload{Barehanded: false})
 				model, cmd := m.finishFight()
+				m.syncOpponentState()
 				return model, cmd
 			}
-
-		// Handle game over and restart
-		case "r":
-			if m.health <= 0 {
-				return initialModel(), nil // Restart the game
-			}
-		case "t":
-			debugMode = !debugMode
-			return m, nil
 		}
 	}
 	return m, nil
@@ -300,15 +635,24 @@ func (m *model) selectCard(index int) *model {
 		case "Weapon":
 			m.equipWeapon(card)
 			m.room = append(m.room[:index], m.room[index+1:]...)
+			m.maybeDealRoom()
 		case "Potion":
 			m.usePotion(card)
 			m.room = append(m.room[:index], m.room[index+1:]...)
+			m.maybeDealRoom()
+			m.sendOpponentAction(scoundrelnet.MsgPotion, struct{}{})
 		case "Monster":
 			m.selectedCard = index
 			m.fightMonster(card)
 			m.choosingFight = true
+		case "Shield", "Poison", "Recharge":
+			m.castSpell(card)
+			m.room = append(m.room[:index], m.room[index+1:]...)
+			m.maybeDealRoom()
 		}
 
+		m.sendOpponentAction(scoundrelnet.MsgSelect, scoundrelnet.SelectPayload{Index: index})
+
 	} else {
 		fmt.Println("Invalid card selection")
 	}
@@ -317,68 +661,169 @@ func (m *model) selectCard(index int) *model {
 
 func (m *model) View() string {
 	s := "--------------------------------------------------\n"
-	if m.health <= 0 {
+
+	switch m.status {
+	case StatusLobby:
+		s += "                Go Scoundrel                \n"
+		s += "--------------------------------------------------\n"
+		s += " Press 'c' for a Classic Dungeon             \n"
+		s += " Press 'e' for an Endless Campaign           \n"
+		s += "--------------------------------------------------\n"
+		return s
+
+	case StatusWin:
+		s += "           Dungeon Cleared! You Win!          \n"
+		s += fmt.Sprintf(" Final Health: %-10d             \n", m.health)
+		s += fmt.Sprintf(" Dungeons Cleared: %-6d             \n", m.dungeonClears)
+		s += fmt.Sprintf(" Score: %-4d                         \n", m.cumulativeScore)
+		s += " Press 'r' to return to the lobby.    \n"
+		s += "--------------------------------------------------\n"
+		return s
+
+	case StatusGameOver:
 		s += "             Game Over!             \n"
 		s += fmt.Sprintf("             Score: %-4d           \n", m.calculateScore())
-		s += " Press 'r' to restart the game.   \n"
+		if m.endlessMode {
+			s += fmt.Sprintf(" Dungeons Cleared: %-6d             \n", m.dungeonClears)
+			s += fmt.Sprintf(" Cumulative Score: %-6d             \n", m.cumulativeScore)
+		}
+		s += " Press 'r' to return to the lobby.   \n"
 		s += "--------------------------------------------------\n"
-	} else {
-		s += fmt.Sprintf(" Health ‚ù§Ô∏è: %-29d \n", m.health)
+		return s
+	}
+
+	s += fmt.Sprintf(" Health ‚ù§Ô∏è: %-29d \n", m.health)
+	s += "--------------------------------------------------\n"
+	s += fmt.Sprintf(" Dungeon üí•: %-25d Cards \n", len(m.dungeon))
+	if m.endlessMode {
+		s += fmt.Sprintf(" Dungeons Cleared üìú: %-18d \n", m.dungeonClears)
+	}
+	if m.ruleset.Name == "wizard" {
+		s += fmt.Sprintf(" Mana: %-6d Shield: %-4d Poison: %-4d Recharge: %-4d \n", m.mana, m.shieldTurns, m.poisonTurns, m.rechargeTurns)
 		s += "--------------------------------------------------\n"
-		s += fmt.Sprintf(" Dungeon üí•: %-25d Cards \n", len(m.dungeon))
+	}
 
-		// Debug mode: display room values
-		if debugMode {
-			s += " Debug: Room values:\n"
-			for _, card := range m.room {
-				s += fmt.Sprintf("   %v\n", card)
-			}
-			s += "--------------------------------------------------\n"
-		} else {
-			s += "--------------------------------------------------\n"
+	// Debug mode: display room values
+	if debugMode {
+		s += " Debug: Room values:\n"
+		for _, card := range m.room {
+			s += fmt.Sprintf("   %v\n", card)
 		}
+		s += "--------------------------------------------------\n"
+	} else {
+		s += "--------------------------------------------------\n"
+	}
 
-		// Show avoid room option if not avoided last room
-		if !m.avoidedLastRoom {
-			s += " Avoid Room? (a)                      \n"
-			s += "--------------------------------------------------\n"
-		}
+	// Show avoid room option if not avoided last room
+	if !m.avoidedLastRoom {
+		s += " Avoid Room? (a)                      \n"
+		s += "--------------------------------------------------\n"
+	}
 
-		roomStr := ""
-		for i, card := range m.room {
-			selected := ""
-			if i == m.selectedCard {
-				selected = "*" // Mark the selected card
-			}
-			roomStr += fmt.Sprintf("[%d:%s%s %d]", i+1, selected, card.Suit, card.Value)
-		}
+	if len(m.undoStack) > 0 {
+		s += fmt.Sprintf(" Undo (u): %-10d moves available \n", len(m.undoStack))
+		s += "--------------------------------------------------\n"
+	}
 
-		s += fmt.Sprintf(" Room üö™: %-32s \n", roomStr)
+	s += " Save (s) / Load (l) / Hint (h)       \n"
+	s += "--------------------------------------------------\n"
+
+	if m.hint != nil {
+		s += fmt.Sprintf(" Hint: %s (expected score %.1f) \n", hintDescription(*m.hint), m.hint.ExpectedScore)
 		s += "--------------------------------------------------\n"
+	}
 
-		if m.choosingFight {
-			s += " Fight Barehanded (b) or With Weapon (w)? \n"
-			s += "--------------------------------------------------\n"
-		} else {
-			weaponStr := fmt.Sprintf("%s %d", m.equippedWeapon.Suit, m.equippedWeapon.Value)
-			if m.equippedWeapon.MonsterValue > 0 {
-				weaponStr += fmt.Sprintf(" (Monster: %d)", m.equippedWeapon.MonsterValue)
-			}
-			s += fmt.Sprintf(" Equipped Weapon üó°Ô∏è: %-28s \n", weaponStr)
-			s += "--------------------------------------------------\n"
-			s += fmt.Sprintf(" Discard Pile ‚ôªÔ∏è: %-21d \n", len(m.discardPile))
-			s += "--------------------------------------------------\n"
+	if m.saveMessage != "" {
+		s += fmt.Sprintf(" %s \n", m.saveMessage)
+		s += "--------------------------------------------------\n"
+	}
+
+	roomStr := ""
+	for i, card := range m.room {
+		selected := ""
+		if i == m.selectedCard {
+			selected = "*" // Mark the selected card
+		}
+		roomStr += fmt.Sprintf("[%d:%s%s %d]", i+1, selected, card.Suit, card.Value)
+	}
+
+	s += fmt.Sprintf(" Room üö™: %-32s \n", roomStr)
+	s += "--------------------------------------------------\n"
+
+	if m.choosingFight {
+		s += " Fight Barehanded (b) or With Weapon (w)? \n"
+		s += "--------------------------------------------------\n"
+	} else {
+		weaponStr := fmt.Sprintf("%s %d", m.equippedWeapon.Suit, m.equippedWeapon.Value)
+		if m.equippedWeapon.MonsterValue > 0 {
+			weaponStr += fmt.Sprintf(" (Monster: %d)", m.equippedWeapon.MonsterValue)
 		}
+		s += fmt.Sprintf(" Equipped Weapon üó°Ô∏è: %-28s \n", weaponStr)
+		s += "--------------------------------------------------\n"
+		s += fmt.Sprintf(" Discard Pile ‚ôªÔ∏è: %-21d \n", len(m.discardPile))
+		s += "--------------------------------------------------\n"
 	}
-	s += fmt.Sprintf(" Score üí∞: %-30d \n", m.calculateScore())
+
+	s += fmt.Sprintf(" Score üí∞: %-30d \n", m.calculateScore())
 	s += "--------------------------------------------------\n"
+
+	if m.networked {
+		opponentWeaponStr := fmt.Sprintf("%s %d", m.opponentWeapon.Suit, m.opponentWeapon.Value)
+		s += fmt.Sprintf(" Opponent Health: %-10d Weapon: %-10s \n", m.opponentHealth, opponentWeaponStr)
+		s += fmt.Sprintf(" Opponent Dungeon: %-6d Discard: %-6d Score: %-6d \n", m.opponentDungeonCount, m.opponentDiscardCount, m.opponentScore)
+		if m.opponentLastAction != "" {
+			s += fmt.Sprintf(" Opponent last move: %-20s \n", m.opponentLastAction)
+		}
+		s += "--------------------------------------------------\n"
+	}
 	return s
 }
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
+	replayFile := flag.String("replay", "", "replay a recorded action log headlessly instead of starting the TUI")
+	hostAddr := flag.String("host", "", "host a two-player race, listening on this address (e.g. :4000)")
+	connectAddr := flag.String("connect", "", "join a two-player race hosted at this address")
+	ruleset := flag.String("ruleset", "classic", "game variant to play: classic, double, or wizard")
+	simulateRuns := flag.Int("simulate", 0, "play this many headless games using the solver's hints and print the score distribution, instead of starting the TUI")
+	flag.Parse()
+
+	if rs, ok := rulesetByName(*ruleset); ok {
+		selectedRuleset = rs
+	} else {
+		fmt.Fprintf(os.Stderr, "unknown ruleset %q, falling back to classic\n", *ruleset)
+	}
+
+	if *simulateRuns > 0 {
+		runSimulate(*simulateRuns)
+		return
+	}
+
+	if *replayFile != "" {
+		if err := runReplay(*replayFile); err != nil {
+			exitWithError(err)
+		}
+		return
+	}
+
+	var initial *model
+	if *hostAddr != "" {
+		m, err := hostMultiplayerGame(*hostAddr)
+		if err != nil {
+			exitWithError(err)
+		}
+		initial = m
+	} else if *connectAddr != "" {
+		m, err := joinMultiplayerGame(*connectAddr)
+		if err != nil {
+			exitWithError(err)
+		}
+		initial = m
+	} else {
+		initial = initialModel()
+	}
+
+	p := tea.NewProgram(initial, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v", err)
-		os.Exit(1)
+		exitWithError(err)
 	}
 }
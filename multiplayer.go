@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	scoundrelnet "go-scoundrel/net"
+)
+
+// Two clients in networked mode each shuffle the same seeded dungeon and
+// play it independently, racing for the highest score. Each side's
+// SELECT/FIGHT/POTION/AVOID messages are NOT applied to any model on the
+// peer - applyOpponentMessage only uses them to update the cosmetic
+// "Opponent last move" line, and periodic STATE messages keep the
+// opponent's health/weapon/score display in sync. There is no shared
+// board and no convergence between the two sides' actual game states;
+// head-to-head alternating rooms, which would need a host to arbitrate
+// whose turn deals the shared room, is left for a follow-up.
+
+// opponentMsg wraps an incoming protocol message as a bubbletea message so
+// it can flow through the normal Update loop.
+type opponentMsg scoundrelnet.Message
+
+// opponentGoneMsg is delivered when the peer connection drops.
+type opponentGoneMsg struct{ err error }
+
+// hostMultiplayerGame blocks waiting for an opponent to connect, then
+// builds a model that shares a freshly seeded dungeon with them.
+func hostMultiplayerGame(addr string) (*model, error) {
+	conn, err := scoundrelnet.Listen(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := time.Now().UnixNano()
+	payload, err := json.Marshal(scoundrelnet.DealPayload{Seed: seed})
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Send(scoundrelnet.Message{Type: scoundrelnet.MsgDeal, Payload: payload}); err != nil {
+		return nil, err
+	}
+
+	return newNetworkedModel(conn, seed), nil
+}
+
+// joinMultiplayerGame connects to a host and waits for the shared dungeon
+// seed before dealing its own copy of the dungeon.
+func joinMultiplayerGame(addr string) (*model, error) {
+	conn, err := scoundrelnet.Connect(addr, "Player")
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := conn.Receive()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Type != scoundrelnet.MsgDeal {
+		return nil, fmt.Errorf("expected DEAL from host, got %s", msg.Type)
+	}
+
+	var deal scoundrelnet.DealPayload
+	if err := json.Unmarshal(msg.Payload, &deal); err != nil {
+		return nil, err
+	}
+
+	return newNetworkedModel(conn, deal.Seed), nil
+}
+
+func newNetworkedModel(conn *scoundrelnet.Conn, seed int64) *model {
+	m := initialModel()
+	m.netConn = conn
+	m.networked = true
+	m.startGameWithSeed(false, seed)
+	return m
+}
+
+// listenForOpponent blocks for the next message from the peer and delivers
+// it to Update; Update re-arms this command each time so the listen loop
+// keeps running for the life of the connection.
+func listenForOpponent(conn *scoundrelnet.Conn) tea.Cmd {
+	return func() tea.Msg {
+		msg, err := conn.Receive()
+		if err != nil {
+			return opponentGoneMsg{err: err}
+		}
+		return opponentMsg(msg)
+	}
+}
+
+// sendOpponentAction notifies the peer of a local action, best-effort - a
+// dropped connection just means the opponent view goes stale, it shouldn't
+// interrupt the local game.
+func (m *model) sendOpponentAction(msgType scoundrelnet.MessageType, payload interface{}) {
+	if m.netConn == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	m.netConn.Send(scoundrelnet.Message{Type: msgType, Payload: encoded})
+}
+
+// syncOpponentState sends a fresh snapshot of local state for the peer's
+// opponent view.
+func (m *model) syncOpponentState() {
+	if m.netConn == nil {
+		return
+	}
+
+	m.sendOpponentAction(scoundrelnet.MsgState, scoundrelnet.StatePayload{
+		Health:       m.health,
+		DungeonCount: len(m.dungeon),
+		DiscardCount: len(m.discardPile),
+		WeaponSuit:   m.equippedWeapon.Suit,
+		WeaponValue:  m.equippedWeapon.Value,
+		Score:        m.calculateScore(),
+	})
+}
+
+// applyOpponentMessage updates the opponent view from an incoming message.
+// Only MsgState changes any numbers shown; SELECT/FIGHT/POTION/AVOID are
+// purely cosmetic here - they set the "Opponent last move" line and never
+// touch any game state, since the two sides play fully independent games.
+func (m *model) applyOpponentMessage(msg scoundrelnet.Message) {
+	switch msg.Type {
+	case scoundrelnet.MsgState:
+		var state scoundrelnet.StatePayload
+		if err := json.Unmarshal(msg.Payload, &state); err != nil {
+			return
+		}
+		m.opponentHealth = state.Health
+		m.opponentDungeonCount = state.DungeonCount
+		m.opponentDiscardCount = state.DiscardCount
+		m.opponentWeapon = Card{Suit: state.WeaponSuit, Value: state.WeaponValue, Type: "Weapon"}
+		m.opponentScore = state.Score
+	case scoundrelnet.MsgSelect:
+		m.opponentLastAction = "picked a card"
+	case scoundrelnet.MsgFight:
+		m.opponentLastAction = "fought a monster"
+	case scoundrelnet.MsgPotion:
+		m.opponentLastAction = "drank a potion"
+	case scoundrelnet.MsgAvoid:
+		m.opponentLastAction = "avoided a room"
+	}
+}
+
+func exitWithError(err error) {
+	fmt.Fprintf(os.Stderr, "Alas, there's been an error: %v", err)
+	os.Exit(1)
+}
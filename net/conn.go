@@ -0,0 +1,48 @@
+package net
+
+import (
+	"bufio"
+	"encoding/json"
+	stdnet "net"
+)
+
+// Conn wraps a TCP connection with newline-delimited JSON framing for
+// Message values.
+type Conn struct {
+	conn   stdnet.Conn
+	reader *bufio.Reader
+}
+
+// NewConn wraps an already-established TCP connection.
+func NewConn(c stdnet.Conn) *Conn {
+	return &Conn{conn: c, reader: bufio.NewReader(c)}
+}
+
+// Send encodes and writes one Message frame.
+func (c *Conn) Send(msg Message) error {
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	_, err = c.conn.Write(encoded)
+	return err
+}
+
+// Receive blocks until the next Message frame arrives.
+func (c *Conn) Receive() (Message, error) {
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return Message{}, err
+	}
+
+	var msg Message
+	err = json.Unmarshal(line, &msg)
+	return msg, err
+}
+
+// Close closes the underlying connection.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,55 @@
+package net
+
+import (
+	"encoding/json"
+	"fmt"
+	stdnet "net"
+)
+
+// Listen starts a lobby on addr (e.g. ":4000"), blocks until a single
+// opponent joins, and returns the connection to them. This is the whole of
+// matchmaking for now: one host, one challenger.
+func Listen(addr string) (*Conn, error) {
+	listener, err := stdnet.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("hosting on %s: %w", addr, err)
+	}
+	defer listener.Close()
+
+	raw, err := listener.Accept()
+	if err != nil {
+		return nil, fmt.Errorf("accepting opponent: %w", err)
+	}
+
+	peer := NewConn(raw)
+
+	join, err := peer.Receive()
+	if err != nil {
+		return nil, fmt.Errorf("reading handshake: %w", err)
+	}
+	if join.Type != MsgJoin {
+		return nil, fmt.Errorf("expected JOIN handshake, got %s", join.Type)
+	}
+
+	return peer, nil
+}
+
+// Connect dials a host at addr and sends the JOIN handshake.
+func Connect(addr, name string) (*Conn, error) {
+	raw, err := stdnet.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+
+	peer := NewConn(raw)
+
+	payload, err := json.Marshal(JoinPayload{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	if err := peer.Send(Message{Type: MsgJoin, Payload: payload}); err != nil {
+		return nil, fmt.Errorf("sending handshake: %w", err)
+	}
+
+	return peer, nil
+}
@@ -0,0 +1,63 @@
+// Package net implements the small wire protocol the two-player race mode
+// uses: a host and a connecting client deal the same seeded dungeon and
+// play it independently, exchanging STATE snapshots so each can show a
+// read-only view of how the other is doing. SELECT/FIGHT/POTION/AVOID
+// messages are advisory only - neither side applies them to the other's
+// game state, so there's no shared board or convergence between the two
+// sides' actual play.
+package net
+
+import "encoding/json"
+
+// MessageType identifies the kind of payload a Message carries.
+type MessageType string
+
+const (
+	MsgJoin   MessageType = "JOIN"   // client -> host: handshake, carries JoinPayload
+	MsgDeal   MessageType = "DEAL"   // host -> client: the shared dungeon seed, carries DealPayload
+	MsgSelect MessageType = "SELECT" // either direction: a room card was chosen, carries SelectPayload
+	MsgFight  MessageType = "FIGHT"  // either direction: how a monster was fought, carries FightPayload
+	MsgPotion MessageType = "POTION" // either direction: a potion was used, carries no payload
+	MsgAvoid  MessageType = "AVOID"  // either direction: the room was avoided, carries no payload
+	MsgState  MessageType = "STATE"  // either direction: a refreshed opponent view, carries StatePayload
+)
+
+// Message is one frame of the protocol: a type tag plus a JSON-encoded
+// payload specific to that type. Frames are newline-delimited JSON on the
+// wire (see Conn).
+type Message struct {
+	Type    MessageType     `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// JoinPayload is sent by a connecting client as its handshake.
+type JoinPayload struct {
+	Name string `json:"name"`
+}
+
+// DealPayload carries the seed the host shuffled the shared dungeon with,
+// so both sides deal identical rooms.
+type DealPayload struct {
+	Seed int64 `json:"seed"`
+}
+
+// SelectPayload identifies which room slot a player picked.
+type SelectPayload struct {
+	Index int `json:"index"`
+}
+
+// FightPayload records whether a monster was fought barehanded.
+type FightPayload struct {
+	Barehanded bool `json:"barehanded"`
+}
+
+// StatePayload is the opponent view rendered alongside the local room: just
+// enough to show how the other player is doing, not their hidden room.
+type StatePayload struct {
+	Health        int    `json:"health"`
+	DungeonCount  int    `json:"dungeonCount"`
+	DiscardCount  int    `json:"discardCount"`
+	WeaponSuit    string `json:"weaponSuit"`
+	WeaponValue   int    `json:"weaponValue"`
+	Score         int    `json:"score"`
+}
@@ -0,0 +1,81 @@
+package main
+
+// Ruleset bundles the tunable constants and the card pack that define a
+// game variant, so alternates can swap in a different deck and different
+// limits. Spell-card effects are not part of the ruleset: main.go handles
+// them by card type.
+type Ruleset struct {
+	Name           string
+	HealthCap      int
+	WeaponLimit    int
+	RoomSize       int
+	PotionsPerTurn int
+	AvoidCooldown  bool // When true, a room can't be avoided twice in a row
+	StartingMana   int  // Mana a new run begins with; only Wizard mode uses this
+	CardPack       CardPack
+}
+
+// CardPack builds the deck for a ruleset. Alternate packs can add new card
+// types (e.g. spells) alongside, or instead of, the classic monster/weapon/
+// potion suits.
+type CardPack interface {
+	BuildDeck() []Card
+}
+
+// classicCardPack is the original Clubs/Spades/Diamonds/Hearts deck.
+type classicCardPack struct{}
+
+func (classicCardPack) BuildDeck() []Card {
+	return createDeck()
+}
+
+// doubleCardPack adds a second, independent weapon suit on top of the
+// classic deck ("Double Scoundrel"), for players who want twice the gear
+// and twice the trap-weapon risk.
+type doubleCardPack struct{}
+
+func (doubleCardPack) BuildDeck() []Card {
+	deck := createDeck()
+	for i := 2; i <= 10; i++ {
+		deck = append(deck, Card{Suit: "Iron", Value: i, Type: "Weapon"})
+	}
+	return deck
+}
+
+// Tuning for the Wizard-mode spell cards: how much a Shield card blocks per
+// fight, how much damage a Poison card ticks per room, and how much mana a
+// Recharge card regenerates per room.
+const (
+	wizardShieldBlockAmount   = 4
+	wizardPoisonDamagePerTurn = 2
+	wizardRechargeManaPerTurn = 2
+)
+
+// wizardCardPack adds Shield/Poison/Recharge spell cards to the classic
+// deck. Casting one costs mana equal to its value; see castSpell.
+type wizardCardPack struct{}
+
+func (wizardCardPack) BuildDeck() []Card {
+	deck := createDeck()
+	for _, spellType := range []string{"Shield", "Poison", "Recharge"} {
+		for i := 2; i <= 6; i++ {
+			deck = append(deck, Card{Suit: "Spell", Value: i, Type: spellType, ManaCost: i})
+		}
+	}
+	return deck
+}
+
+var builtinRulesets = map[string]Ruleset{
+	"classic": {Name: "classic", HealthCap: 20, WeaponLimit: 14, RoomSize: 4, PotionsPerTurn: 1, AvoidCooldown: true, CardPack: classicCardPack{}},
+	"double":  {Name: "double", HealthCap: 20, WeaponLimit: 14, RoomSize: 4, PotionsPerTurn: 1, AvoidCooldown: true, CardPack: doubleCardPack{}},
+	"wizard":  {Name: "wizard", HealthCap: 20, WeaponLimit: 14, RoomSize: 4, PotionsPerTurn: 1, AvoidCooldown: true, StartingMana: 10, CardPack: wizardCardPack{}},
+}
+
+var defaultRuleset = builtinRulesets["classic"]
+
+// rulesetByName looks up a registered built-in ruleset by the name passed
+// to --ruleset.
+func rulesetByName(name string) (Ruleset, bool) {
+	rs, ok := builtinRulesets[name]
+	return rs, ok
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	saveFileName      = "save.json"
+	actionLogFileName = "actions.log"
+)
+
+// saveData is the JSON-serializable subset of model that a save file
+// captures: enough to resume a game exactly where it left off.
+type saveData struct {
+	Status              GameStatus `json:"status"`
+	Health              int        `json:"health"`
+	Dungeon             []Card     `json:"dungeon"`
+	Room                []Card     `json:"room"`
+	EquippedWeapon      Card       `json:"equippedWeapon"`
+	DiscardPile         []Card     `json:"discardPile"`
+	SelectedCard        int        `json:"selectedCard"`
+	CardsChosen         int        `json:"cardsChosen"`
+	ChoosingFight       bool       `json:"choosingFight"`
+	WeaponLimit         int        `json:"weaponLimit"`
+	AvoidedLastRoom     bool       `json:"avoidedLastRoom"`
+	PotionsUsedThisTurn int        `json:"potionsUsedThisTurn"`
+	EndlessMode         bool       `json:"endlessMode"`
+	DungeonClears       int        `json:"dungeonClears"`
+	CumulativeScore     int        `json:"cumulativeScore"`
+	Seed                int64      `json:"seed"`
+	Ruleset             string     `json:"ruleset"`
+	Mana                int        `json:"mana"`
+	ShieldTurns         int        `json:"shieldTurns"`
+	PoisonTurns         int        `json:"poisonTurns"`
+	RechargeTurns       int        `json:"rechargeTurns"`
+}
+
+// configDir returns the OS-appropriate directory for go-scoundrel's save
+// data, creating it if necessary.
+func configDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(base, "go-scoundrel")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// saveGame writes the current model state to disk as JSON.
+func (m *model) saveGame() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	data := saveData{
+		Status:              m.status,
+		Health:              m.health,
+		Dungeon:             m.dungeon,
+		Room:                m.room,
+		EquippedWeapon:      m.equippedWeapon,
+		DiscardPile:         m.discardPile,
+		SelectedCard:        m.selectedCard,
+		CardsChosen:         m.cardsChosen,
+		ChoosingFight:       m.choosingFight,
+		WeaponLimit:         m.weaponLimit,
+		AvoidedLastRoom:     m.avoidedLastRoom,
+		PotionsUsedThisTurn: m.potionsUsedThisTurn,
+		EndlessMode:         m.endlessMode,
+		DungeonClears:       m.dungeonClears,
+		CumulativeScore:     m.cumulativeScore,
+		Seed:                m.seed,
+		Ruleset:             m.ruleset.Name,
+		Mana:                m.mana,
+		ShieldTurns:         m.shieldTurns,
+		PoisonTurns:         m.poisonTurns,
+		RechargeTurns:       m.rechargeTurns,
+	}
+
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, saveFileName), encoded, 0o644)
+}
+
+// loadGame reads a previously saved game back into the model, replacing its
+// current state.
+func (m *model) loadGame() error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, saveFileName))
+	if err != nil {
+		return err
+	}
+
+	var data saveData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return err
+	}
+
+	m.status = data.Status
+	m.health = data.Health
+	m.dungeon = data.Dungeon
+	m.room = data.Room
+	m.equippedWeapon = data.EquippedWeapon
+	m.discardPile = data.DiscardPile
+	m.selectedCard = data.SelectedCard
+	m.cardsChosen = data.CardsChosen
+	m.weaponLimit = data.WeaponLimit
+	m.avoidedLastRoom = data.AvoidedLastRoom
+	m.potionsUsedThisTurn = data.PotionsUsedThisTurn
+	m.endlessMode = data.EndlessMode
+	m.dungeonClears = data.DungeonClears
+	m.cumulativeScore = data.CumulativeScore
+	m.seed = data.Seed
+	m.rng = rand.New(rand.NewSource(data.Seed))
+	m.choosingFight = data.ChoosingFight
+	m.undoStack = nil
+	m.mana = data.Mana
+	m.shieldTurns = data.ShieldTurns
+	m.poisonTurns = data.PoisonTurns
+	m.rechargeTurns = data.RechargeTurns
+
+	if rs, ok := rulesetByName(data.Ruleset); ok {
+		m.ruleset = rs
+	}
+
+	return nil
+}
+
+// recordedAction is one entry in the action log: a key press and when it
+// happened. The record that starts a run also carries the RNG seed so a
+// replay can reproduce the same deck order.
+type recordedAction struct {
+	Time time.Time `json:"time"`
+	Key  string    `json:"key"`
+	Seed int64     `json:"seed,omitempty"`
+}
+
+func actionLogPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, actionLogFileName), nil
+}
+
+// logGameStart truncates the action log and records the lobby key ("c" or
+// "e") along with the seed it started the run with.
+func logGameStart(key string, seed int64) error {
+	path, err := actionLogPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeAction(f, recordedAction{Time: time.Now(), Key: key, Seed: seed})
+}
+
+// logAction appends a key press to the action log in the config dir so a
+// run can later be replayed with --replay.
+func logAction(key string) error {
+	path, err := actionLogPath()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeAction(f, recordedAction{Time: time.Now(), Key: key})
+}
+
+func writeAction(f *os.File, entry recordedAction) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	_, err = f.Write(encoded)
+	return err
+}
+
+// loadActionLog reads back every recorded key press, in order, for replay.
+func loadActionLog(path string) ([]recordedAction, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []recordedAction
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	for decoder.More() {
+		var entry recordedAction
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, err
+		}
+		actions = append(actions, entry)
+	}
+	return actions, nil
+}
+
+// runReplay reconstructs a game from a recorded action log and re-applies
+// every key press against the seeded deck the original run used, printing
+// the final board and score.
+func runReplay(path string) error {
+	actions, err := loadActionLog(path)
+	if err != nil {
+		return fmt.Errorf("loading replay log: %w", err)
+	}
+	if len(actions) == 0 {
+		return fmt.Errorf("replay log %q has no recorded actions", path)
+	}
+
+	start := actions[0]
+	m := initialModel()
+	m.replaying = true
+	m.startGameWithSeed(start.Key == "e", start.Seed)
+
+	for _, action := range actions[1:] {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(action.Key)})
+	}
+
+	fmt.Println(m.View())
+	fmt.Printf("Replay complete: %d actions replayed, final score %d\n", len(actions)-1, m.calculateScore())
+	return nil
+}
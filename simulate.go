@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"go-scoundrel/solver"
+)
+
+// maxSimulateActions bounds a single simulated run so a solver bug (or a
+// ruleset the solver can't make progress against) can't spin forever
+// instead of finishing the batch.
+const maxSimulateActions = 2000
+
+// runSimulate plays n headless games entirely by following the solver's
+// hints and prints the resulting score distribution - a quick way to
+// balance-test a ruleset without playing it by hand.
+func runSimulate(n int) {
+	scores := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		scores = append(scores, simulateOne(int64(i)))
+	}
+	printScoreDistribution(scores)
+}
+
+// simulateOne plays a single seeded run to completion, always taking the
+// solver's recommended action, and returns the final score.
+func simulateOne(seed int64) int {
+	m := initialModel()
+	m.startGameWithSeed(false, seed)
+
+	for i := 0; i < maxSimulateActions; i++ {
+		if m.status != StatusPlaying {
+			break
+		}
+		result := solver.Solve(m.toSolverState())
+		m.applySolverAction(result.Action)
+	}
+
+	return m.calculateScore()
+}
+
+func printScoreDistribution(scores []int) {
+	sorted := append([]int{}, scores...)
+	sort.Ints(sorted)
+
+	sum := 0
+	wins := 0
+	for _, s := range sorted {
+		sum += s
+		if s > 0 {
+			wins++
+		}
+	}
+
+	fmt.Printf("Simulated %d games\n", len(sorted))
+	fmt.Printf("Mean score: %.2f\n", float64(sum)/float64(len(sorted)))
+	fmt.Printf("Min / Median / Max: %d / %d / %d\n", sorted[0], sorted[len(sorted)/2], sorted[len(sorted)-1])
+	fmt.Printf("Survived (score > 0): %d / %d\n", wins, len(sorted))
+}
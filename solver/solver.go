@@ -0,0 +1,434 @@
+// Package solver computes the optimal next move in a game of Scoundrel (or
+// a ruleset variant) via depth-first expectiminimax search: it maximizes
+// over the player's legal choices and averages over the uniform random
+// draw the dungeon deals when the room is refilled.
+//
+// The solver has no dependency on package main - it works entirely in
+// terms of its own Card/State types - so the main game converts a model
+// into a State (see hint.go) rather than the solver importing the game.
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Card is the solver's view of a card: just enough to reason about combat
+// and scoring. Suit doesn't affect any rule, so it's dropped - this also
+// lets the dungeon and room be reasoned about as multisets, per the
+// expectiminimax search below.
+type Card struct {
+	Value int
+	Type  string // "Monster", "Weapon", "Potion"
+}
+
+// State is a canonical snapshot of everything needed to compute the
+// optimal next action: player status, the room, and the remaining dungeon
+// as a multiset (its order is unknown to the player, so the solver only
+// ever reasons about what's left, not what's next).
+type State struct {
+	Health             int
+	HealthCap          int
+	Room               []Card
+	RoomSize           int
+	HasWeapon          bool
+	WeaponValue        int
+	WeaponLimit        int // The maximum monster value the current weapon can be used on
+	MaxWeaponLimit     int // WeaponLimit a freshly equipped weapon resets to
+	AvoidedLastRoom    bool
+	PotionUsedThisTurn bool
+	Dungeon            []Card
+}
+
+// ActionKind identifies what kind of move an Action represents.
+type ActionKind int
+
+const (
+	ActionNone ActionKind = iota
+	ActionAvoid
+	ActionPick
+)
+
+// Action is one legal move: avoid the room, or pick a room card (and, for
+// monsters, whether to fight barehanded).
+type Action struct {
+	Kind       ActionKind
+	RoomIndex  int
+	Barehanded bool
+}
+
+func (a Action) String() string {
+	switch a.Kind {
+	case ActionAvoid:
+		return "avoid the room"
+	case ActionPick:
+		if a.Barehanded {
+			return fmt.Sprintf("pick card %d and fight barehanded", a.RoomIndex+1)
+		}
+		return fmt.Sprintf("pick card %d", a.RoomIndex+1)
+	default:
+		return "wait"
+	}
+}
+
+// Result is the solver's verdict for a state: the best action to take and
+// the expected final score if play continues optimally from here.
+type Result struct {
+	Action        Action
+	ExpectedScore float64
+}
+
+// maxExhaustiveDungeon bounds how large a remaining dungeon the solver
+// will search exactly. Expectiminimax over the full multiset branches on
+// every distinct card left in the dungeon at every draw, so past this
+// size Solve falls back to a one-ply greedy heuristic instead of hanging
+// the 'h' hint or --simulate.
+const maxExhaustiveDungeon = 10
+
+// Solve computes the optimal next action for state and its expected final
+// score. When the dungeon holds more than maxExhaustiveDungeon cards it
+// returns greedyHint's one-ply choice instead, whose ExpectedScore is that
+// heuristic's estimate rather than an expected final score.
+func Solve(state State) Result {
+	if len(state.Dungeon) > maxExhaustiveDungeon {
+		return greedyHint(state)
+	}
+
+	s := &searcher{memo: map[string]float64{}, best: math.Inf(-1)}
+	return s.bestAction(state)
+}
+
+// searcher holds memoization and pruning state for one top-level Solve call.
+type searcher struct {
+	memo map[string]float64
+	best float64 // best fully-resolved expected score found so far, for pruning
+}
+
+func (s *searcher) bestAction(state State) Result {
+	if state.Health <= 0 {
+		return Result{Action: Action{Kind: ActionNone}, ExpectedScore: deadScore(state)}
+	}
+	if needsRefill(state) {
+		return Result{Action: Action{Kind: ActionNone}, ExpectedScore: s.expectedAfterDraw(state)}
+	}
+
+	actions := legalActions(state)
+	if len(actions) == 0 {
+		return Result{Action: Action{Kind: ActionNone}, ExpectedScore: finalScore(state)}
+	}
+
+	best := Result{Action: actions[0], ExpectedScore: math.Inf(-1)}
+	for _, action := range actions {
+		score := s.valueOf(applyAction(state, action))
+		if score > best.ExpectedScore {
+			best = Result{Action: action, ExpectedScore: score}
+		}
+	}
+
+	if best.ExpectedScore > s.best {
+		s.best = best.ExpectedScore
+	}
+	return best
+}
+
+// valueOf is the memoized expected-score-from-here recursion: it maximizes
+// over the player's legal actions once the room is resolved, deferring to
+// expectedAfterDraw whenever the room needs refilling from the dungeon.
+func (s *searcher) valueOf(state State) float64 {
+	key := state.canonicalKey()
+	if v, ok := s.memo[key]; ok {
+		return v
+	}
+
+	// Admissible pruning: if even an optimistic bound on this branch can't
+	// beat the best fully-resolved score found elsewhere, stop exploring it.
+	if bound := upperBound(state); bound <= s.best {
+		return bound
+	}
+
+	var value float64
+	switch {
+	case state.Health <= 0:
+		value = deadScore(state)
+	case needsRefill(state):
+		value = s.expectedAfterDraw(state)
+	default:
+		actions := legalActions(state)
+		if len(actions) == 0 {
+			value = finalScore(state)
+		} else {
+			value = math.Inf(-1)
+			for _, action := range actions {
+				if v := s.valueOf(applyAction(state, action)); v > value {
+					value = v
+				}
+			}
+		}
+	}
+
+	if value > s.best {
+		s.best = value
+	}
+	s.memo[key] = value
+	return value
+}
+
+// expectedAfterDraw averages valueOf over every distinct card that could
+// be drawn next from the dungeon, weighted by how many copies remain -
+// the "chance" ply of the expectiminimax search. Called when
+// needsRefill(state) holds, i.e. the room is short a card and the
+// dungeon isn't empty; it keeps drawing one card at a time until the room
+// is back to RoomSize or the dungeon runs out.
+func (s *searcher) expectedAfterDraw(state State) float64 {
+	total := len(state.Dungeon)
+	counts := map[Card]int{}
+	for _, c := range state.Dungeon {
+		counts[c]++
+	}
+
+	expected := 0.0
+	for card, count := range counts {
+		probability := float64(count) / float64(total)
+
+		next := state
+		next.Room = append(append([]Card{}, state.Room...), card)
+		next.Dungeon = removeOne(state.Dungeon, card)
+
+		if len(next.Room) < next.RoomSize && len(next.Dungeon) > 0 {
+			expected += probability * s.expectedAfterDraw(next)
+		} else {
+			expected += probability * s.valueOf(next)
+		}
+	}
+	return expected
+}
+
+// needsRefill reports whether state is mid-draw: the room has shrunk to
+// the carry-one-card point (the same RoomSize-3 threshold applyAction
+// resets PotionUsedThisTurn at) or been emptied by an avoid, and the
+// dungeon still has cards to deal. Refilling after every single pick
+// instead of after three would silently reset PotionUsedThisTurn each
+// turn, letting every potion in the room heal for free.
+func needsRefill(state State) bool {
+	return len(state.Room) <= state.RoomSize-3 && len(state.Dungeon) > 0
+}
+
+// legalActions enumerates every move available from state: avoiding the
+// room (only before any card in it has been touched) and picking each
+// room card (monsters offer both a barehanded and a weapon branch when a
+// weapon is equipped).
+func legalActions(state State) []Action {
+	var actions []Action
+
+	if !state.AvoidedLastRoom && len(state.Room) == state.RoomSize {
+		actions = append(actions, Action{Kind: ActionAvoid})
+	}
+
+	for i, card := range state.Room {
+		if card.Type != "Monster" {
+			actions = append(actions, Action{Kind: ActionPick, RoomIndex: i})
+			continue
+		}
+
+		actions = append(actions, Action{Kind: ActionPick, RoomIndex: i, Barehanded: true})
+		if state.HasWeapon {
+			actions = append(actions, Action{Kind: ActionPick, RoomIndex: i, Barehanded: false})
+		}
+	}
+
+	return actions
+}
+
+// applyAction resolves the deterministic part of taking action: combat
+// damage, potion healing, and weapon equipping. It never draws from the
+// dungeon - a room left short of RoomSize is picked up by needsRefill and
+// resolved stochastically by expectedAfterDraw.
+func applyAction(state State, action Action) State {
+	next := state
+
+	switch action.Kind {
+	case ActionAvoid:
+		next.Dungeon = append(append([]Card{}, state.Dungeon...), state.Room...)
+		next.Room = nil
+		next.AvoidedLastRoom = true
+		return next
+
+	case ActionPick:
+		card := state.Room[action.RoomIndex]
+		next.Room = removeAt(state.Room, action.RoomIndex)
+		next.AvoidedLastRoom = false
+
+		switch card.Type {
+		case "Weapon":
+			next.HasWeapon = true
+			next.WeaponValue = card.Value
+			next.WeaponLimit = state.MaxWeaponLimit
+		case "Potion":
+			if !state.PotionUsedThisTurn {
+				next.Health += card.Value
+				if next.Health > next.HealthCap {
+					next.Health = next.HealthCap
+				}
+				next.PotionUsedThisTurn = true
+			}
+		case "Monster":
+			damage := card.Value
+			if !action.Barehanded && state.HasWeapon && card.Value <= state.WeaponLimit {
+				if blow := card.Value - state.WeaponValue; blow > 0 {
+					damage = blow
+				} else {
+					damage = 0
+				}
+				next.WeaponLimit = card.Value
+			}
+			next.Health -= damage
+		}
+
+		// Three of the room's four cards resolved: the fourth carries over
+		// and the room is topped back up, mirroring the live game's rule.
+		if len(next.Room) == next.RoomSize-3 {
+			next.AvoidedLastRoom = false
+			next.PotionUsedThisTurn = false
+		}
+		return next
+	}
+
+	return next
+}
+
+// deadScore mirrors the live game's score-on-death: current (negative)
+// health minus the value of every monster still left in the dungeon.
+func deadScore(state State) float64 {
+	score := state.Health
+	for _, c := range state.Dungeon {
+		if c.Type == "Monster" {
+			score -= c.Value
+		}
+	}
+	return float64(score)
+}
+
+// finalScore is the score when the dungeon and room are both empty and
+// the player is still alive: their remaining health. (The live game's
+// "ended on a potion at full health" bonus isn't modeled here - the
+// solver doesn't track discard history, only a multiset of what's left.)
+func finalScore(state State) float64 {
+	return float64(state.Health)
+}
+
+// upperBound is an optimistic score estimate for pruning: health plus
+// every potion still in play, capped at the health cap.
+func upperBound(state State) float64 {
+	bound := state.Health
+	for _, c := range state.Room {
+		if c.Type == "Potion" {
+			bound += c.Value
+		}
+	}
+	for _, c := range state.Dungeon {
+		if c.Type == "Potion" {
+			bound += c.Value
+		}
+	}
+	if bound > state.HealthCap {
+		bound = state.HealthCap
+	}
+	return float64(bound)
+}
+
+func removeAt(cards []Card, index int) []Card {
+	out := make([]Card, 0, len(cards)-1)
+	out = append(out, cards[:index]...)
+	out = append(out, cards[index+1:]...)
+	return out
+}
+
+func removeOne(cards []Card, card Card) []Card {
+	out := make([]Card, 0, len(cards)-1)
+	removed := false
+	for _, c := range cards {
+		if !removed && c == card {
+			removed = true
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// canonicalKey collapses a state to a string key for memoization: two
+// states with the same health/weapon/flags and the same room and dungeon
+// multisets are interchangeable for search purposes.
+func (state State) canonicalKey() string {
+	weapon := "none"
+	if state.HasWeapon {
+		weapon = fmt.Sprintf("w%d/%d", state.WeaponValue, state.WeaponLimit)
+	}
+	return fmt.Sprintf("h%d|%s|av%v|pot%v|room%s|dun%s",
+		state.Health, weapon, state.AvoidedLastRoom, state.PotionUsedThisTurn,
+		sortedCardKeys(state.Room), sortedCardKeys(state.Dungeon))
+}
+
+func sortedCardKeys(cards []Card) []string {
+	keys := make([]string, len(cards))
+	for i, c := range cards {
+		keys[i] = fmt.Sprintf("%s%d", c.Type, c.Value)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// greedyHint is the fallback used once the remaining dungeon is too large
+// to search exhaustively: a one-ply heuristic that favors healing when
+// hurt, equipping strong weapons, and taking the least damage available.
+func greedyHint(state State) Result {
+	if state.Health <= 0 {
+		return Result{Action: Action{Kind: ActionNone}, ExpectedScore: deadScore(state)}
+	}
+
+	actions := legalActions(state)
+	if len(actions) == 0 {
+		return Result{Action: Action{Kind: ActionNone}, ExpectedScore: finalScore(state)}
+	}
+
+	best := Result{Action: actions[0], ExpectedScore: math.Inf(-1)}
+	for _, action := range actions {
+		score := heuristicScore(state, action)
+		if score > best.ExpectedScore {
+			best = Result{Action: action, ExpectedScore: score}
+		}
+	}
+	return best
+}
+
+func heuristicScore(state State, action Action) float64 {
+	if action.Kind == ActionAvoid {
+		return float64(state.Health) - 1 // mildly discourage avoiding forever
+	}
+
+	card := state.Room[action.RoomIndex]
+	switch card.Type {
+	case "Potion":
+		if state.PotionUsedThisTurn {
+			return float64(state.Health) - 5 // would be wasted
+		}
+		healed := state.Health + card.Value
+		if healed > state.HealthCap {
+			healed = state.HealthCap
+		}
+		return float64(healed)
+	case "Weapon":
+		return float64(state.Health) + float64(card.Value)/2
+	default: // Monster
+		damage := card.Value
+		if !action.Barehanded && state.HasWeapon && card.Value <= state.WeaponLimit {
+			if blow := card.Value - state.WeaponValue; blow > 0 {
+				damage = blow
+			} else {
+				damage = 0
+			}
+		}
+		return float64(state.Health - damage)
+	}
+}